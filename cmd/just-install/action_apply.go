@@ -0,0 +1,76 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/just-install/just-install/pkg/manifest"
+	"github.com/just-install/just-install/pkg/state"
+)
+
+// handleApply installs the exact set of package+version+arch recorded in a lockfile produced by
+// "just-install freeze".
+func handleApply(c *cli.Context) error {
+	path := c.Args().First()
+	if isEmptyString(path) {
+		return errors.New("usage: just-install apply <lockfile>")
+	}
+
+	m, err := manifest.Load(path)
+	if err != nil {
+		return err
+	}
+
+	registry, err := loadRegistry(c, c.Bool("force"))
+	if err != nil {
+		return err
+	}
+
+	db, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("could not load state database: %w", err)
+	}
+
+	hasErrors := false
+
+	for name, pinned := range m.Packages {
+		entry, ok := registry.Packages[name]
+		if !ok {
+			log.Println("WARNING: unknown package", name)
+			continue
+		}
+
+		if err := installPinned(db, name, entry, pinned, c.Bool("force")); err != nil {
+			log.Printf("error installing %v: %v", name, err)
+			hasErrors = true
+		}
+	}
+
+	if err := db.Save(); err != nil {
+		log.Printf("could not save state database: %v", err)
+	}
+
+	if hasErrors {
+		return errors.New("encountered errors installing packages (see the log for details)")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/just-install/just-install/pkg/manifest"
+	"github.com/just-install/just-install/pkg/winuninstall"
+)
+
+// handleFreeze writes a lockfile pinning every registry package that looks installed (matched
+// against the Windows Uninstall registry by display name) to its currently installed version, so
+// the machine's package set can be reproduced elsewhere with "just-install apply".
+func handleFreeze(c *cli.Context) error {
+	out := c.Args().First()
+	if isEmptyString(out) {
+		return errors.New("usage: just-install freeze <lockfile>")
+	}
+
+	registry, err := loadRegistry(c, false)
+	if err != nil {
+		return err
+	}
+
+	installed, err := winuninstall.List()
+	if err != nil {
+		return fmt.Errorf("could not enumerate installed programs: %w", err)
+	}
+
+	arch, err := getInstallArch(c.String("arch"))
+	if err != nil {
+		return err
+	}
+
+	m := &manifest.Manifest{Packages: map[string]manifest.Entry{}}
+
+	for name, entry := range registry.Packages {
+		found, ok := winuninstall.Find(installed, name, entry.DisplayName)
+		if !ok {
+			continue
+		}
+
+		installerURL := entry.Installer.X86_64
+		if arch == "x86" || isEmptyString(installerURL) {
+			installerURL = entry.Installer.X86
+		}
+
+		installerURL, err := expandString(installerURL, map[string]string{"version": found.DisplayVersion})
+		if err != nil {
+			return fmt.Errorf("could not expand installer URL's template string for %v: %w", name, err)
+		}
+
+		sha256, _ := entry.Installer.ChecksumFor(arch)
+
+		m.Packages[name] = manifest.Entry{
+			Version:      found.DisplayVersion,
+			Arch:         arch,
+			InstallerURL: installerURL,
+			Sha256:       sha256,
+		}
+	}
+
+	return manifest.Save(out, m)
+}
@@ -0,0 +1,64 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/just-install/just-install/pkg/importers"
+)
+
+// handleImport converts a package definition from an external community registry (WinGet,
+// Chocolatey or Scoop) into the JSON stanza for a registry4.Package, and prints it so a
+// maintainer can paste it into the registry.
+//
+// just-install has no id resolution against the real WinGet/Chocolatey/Scoop registries, so the
+// argument is not a package id: it's the manifest itself (a URL or local file path; Chocolatey
+// additionally accepts a local package directory), exactly as importers.Adapter.Import expects.
+func handleImport(c *cli.Context) error {
+	source := c.String("source")
+	if isEmptyString(source) {
+		return errors.New("--source is required (one of: winget, chocolatey, scoop)")
+	}
+
+	adapter, ok := importers.Adapters[source]
+	if !ok {
+		return fmt.Errorf("unknown import source: %v", source)
+	}
+
+	manifest := c.Args().First()
+	if isEmptyString(manifest) {
+		return errors.New("usage: just-install import --source=<winget|chocolatey|scoop> <manifest URL or path>")
+	}
+
+	pkg, err := adapter.Import(manifest)
+	if err != nil {
+		return fmt.Errorf("could not import %v: %w", manifest, err)
+	}
+
+	b, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(b))
+
+	return nil
+}
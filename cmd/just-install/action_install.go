@@ -22,8 +22,11 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/gotopkg/mslnk/pkg/mslnk"
 	"github.com/ungerik/go-dry"
@@ -32,10 +35,13 @@ import (
 	"github.com/just-install/just-install/pkg/cmd"
 	"github.com/just-install/just-install/pkg/fetch"
 	"github.com/just-install/just-install/pkg/installer"
+	"github.com/just-install/just-install/pkg/manifest"
 	"github.com/just-install/just-install/pkg/paths"
 	"github.com/just-install/just-install/pkg/platform"
 	"github.com/just-install/just-install/pkg/registry4"
+	"github.com/just-install/just-install/pkg/state"
 	"github.com/just-install/just-install/pkg/strings2"
+	"github.com/just-install/just-install/pkg/verify"
 )
 
 var (
@@ -47,20 +53,39 @@ func handleInstall(c *cli.Context) error {
 	force := c.Bool("force")
 	onlyDownload := c.Bool("download-only")
 	onlyShims := c.Bool("shim")
+	skipVerify := c.Bool("insecure-skip-verify")
 
 	registry, err := loadRegistry(c, force)
 	if err != nil {
 		return err
 	}
 
+	lockfile, err := maybeLoadLockfile(c.String("lockfile"))
+	if err != nil {
+		return fmt.Errorf("could not load lockfile: %w", err)
+	}
+
+	db, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("could not load state database: %w", err)
+	}
+
 	arch, err := getInstallArch(c.String("arch"))
 	if err != nil {
 		return err
 	}
 
+	jobs := c.Int("jobs")
+	if jobs < 1 {
+		jobs = defaultJobs()
+	}
+
 	printInteractivePackages(registry.Packages, c.Args().Slice())
 
-	// Install packages
+	// Resolve the requested packages (applying any lockfile pin) and split off shim-only requests,
+	// which don't need a download at all.
+	var toFetch []installJob
+
 	hasErrors := false
 
 	for _, pkg := range c.Args().Slice() {
@@ -70,19 +95,28 @@ func handleInstall(c *cli.Context) error {
 			continue
 		}
 
-		options, err := entry.Installer.OptionsForArch(arch)
-		if err != nil {
-			return err
+		if pinned, ok := lockfile.Packages[pkg]; ok {
+			entry = pinEntry(entry, pinned)
 		}
 
 		if onlyShims {
+			options, err := entry.Installer.OptionsForArch(arch)
+			if err != nil {
+				return err
+			}
+
 			mustCreateShims(options.Shims, entry.Version)
 			continue
 		}
 
-		installerPath, err := fetchInstaller(entry, arch, force)
-		if err != nil {
-			log.Printf("error downloading %v: %v", pkg, err)
+		toFetch = append(toFetch, installJob{pkg: pkg, entry: entry})
+	}
+
+	// Installers can't safely run in parallel on Windows, but downloading them can: fetch every
+	// requested installer concurrently, then install them one at a time.
+	for _, res := range fetchConcurrently(toFetch, arch, force, skipVerify, jobs) {
+		if res.err != nil {
+			log.Printf("error downloading %v: %v", res.pkg, res.err)
 			hasErrors = true
 			continue
 		}
@@ -91,25 +125,14 @@ func handleInstall(c *cli.Context) error {
 			continue
 		}
 
-		installerPath, err = maybeExtractContainer(installerPath, options)
-		if err != nil {
-			return err
-		}
-
-		if err := install(installerPath, entry.Installer.Kind, options); err != nil {
-			log.Printf("error installing %v: %v", pkg, err)
+		if err := installFetched(db, res.pkg, res.entry, arch, res.installerPath); err != nil {
+			log.Printf("error installing %v: %v", res.pkg, err)
 			hasErrors = true
-			continue
-		}
-
-		if len(options.Shims) > 0 {
-			if err := createShims(options.Shims, entry.Version); err != nil {
-				log.Printf("could not create shims for: %v due to %v", pkg, err)
-				hasErrors = true
-				continue
-			}
 		}
+	}
 
+	if err := db.Save(); err != nil {
+		log.Printf("could not save state database: %v", err)
 	}
 
 	if hasErrors {
@@ -172,8 +195,11 @@ func printInteractivePackages(packageMap registry4.PackageMap, requestedPackages
 	log.Println("")
 }
 
-// fetchInstaller fetches the installer for the given package and returns
-func fetchInstaller(entry *registry4.Package, arch string, overwrite bool) (string, error) {
+// fetchInstaller fetches the installer for the given package and returns its path on disk, after
+// checking it against any checksum/signature declared in the registry (unless skipVerify is set).
+// progress, if non-nil, is shared with every other concurrent fetchInstaller call in the same
+// batch so their download progress is logged as one combined total.
+func fetchInstaller(entry *registry4.Package, arch string, overwrite, skipVerify bool, progress *fetch.Progress) (string, error) {
 	// Sanity check
 	if isEmptyString(entry.Installer.X86) && isEmptyString(entry.Installer.X86_64) {
 		return "", errors.New("package entry is missing both 32-bit and 64-bit installers")
@@ -212,10 +238,33 @@ func fetchInstaller(entry *registry4.Package, arch string, overwrite bool) (stri
 	ret, err := fetch.Fetch(installerURL, &fetch.Options{
 		Destination: downloadDir,
 		Overwrite:   overwrite,
-		Progress:    true,
+		Progress:    progress,
 	})
+	if err != nil {
+		return "", err
+	}
+
+	if skipVerify {
+		return ret, nil
+	}
 
-	return ret, err
+	sha256, sha512 := entry.Installer.ChecksumFor(arch)
+	verifyOpts := verify.Options{Sha256: sha256, Sha512: sha512}
+
+	if sigURL := entry.Installer.SignatureURLFor(arch); !isEmptyString(sigURL) {
+		verifyOpts.Signature = &verify.Signature{
+			Kind:      entry.Installer.Signature.Kind,
+			PublicKey: entry.Installer.Signature.PublicKey,
+			URL:       sigURL,
+		}
+	}
+
+	if err := verify.File(ret, verifyOpts); err != nil {
+		os.Remove(ret)
+		return "", fmt.Errorf("could not verify installer for %v: %w", installerURL, err)
+	}
+
+	return ret, nil
 }
 
 func maybeExtractContainer(path string, options *registry4.Options) (string, error) {
@@ -390,46 +439,241 @@ func environMap() map[string]string {
 	return ret
 }
 
+// installJob is a package resolved and ready to be fetched.
+type installJob struct {
+	pkg   string
+	entry *registry4.Package
+}
+
+// fetchResult is the outcome of fetching a single installJob's installer.
+type fetchResult struct {
+	installJob
+	installerPath string
+	err           error
+}
+
+// defaultJobs returns the default size of the download worker pool: one worker per CPU, capped at
+// 4 since most installers are fetched from a handful of slow vendor CDNs rather than being
+// bottlenecked on local parallelism.
+func defaultJobs() int {
+	if n := runtime.NumCPU(); n < 4 {
+		return n
+	}
+
+	return 4
+}
+
+// fetchConcurrently downloads the installer for every job using up to concurrency workers,
+// returning one result per job in the same order jobs were given. All workers share a single
+// fetch.Progress, so their combined download progress is logged as one aggregate total rather
+// than one independent line per worker.
+func fetchConcurrently(jobs []installJob, arch string, force, skipVerify bool, concurrency int) []fetchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]fetchResult, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	progress := fetch.NewProgress()
+
+	var wg sync.WaitGroup
+
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, job installJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			installerPath, err := fetchInstaller(job.entry, arch, force, skipVerify, progress)
+			results[i] = fetchResult{installJob: job, installerPath: installerPath, err: err}
+		}(i, job)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// installOne fetches, verifies and runs the installer for a single package, then tracks the
+// result in db. It's shared by installPinned and handleUpgrade, which install one package at a
+// time rather than through handleInstall's worker pool.
+func installOne(db *state.DB, pkg string, entry *registry4.Package, arch string, force, skipVerify, onlyDownload bool) error {
+	installerPath, err := fetchInstaller(entry, arch, force, skipVerify, fetch.NewProgress())
+	if err != nil {
+		return fmt.Errorf("error downloading: %w", err)
+	}
+
+	if onlyDownload {
+		return nil
+	}
+
+	return installFetched(db, pkg, entry, arch, installerPath)
+}
+
+// installFetched runs the install/shim/state-tracking steps for a package whose installer has
+// already been downloaded to installerPath.
+func installFetched(db *state.DB, pkg string, entry *registry4.Package, arch, installerPath string) error {
+	options, err := entry.Installer.OptionsForArch(arch)
+	if err != nil {
+		return err
+	}
+
+	installerPath, err = maybeExtractContainer(installerPath, options)
+	if err != nil {
+		return err
+	}
+
+	if err := install(installerPath, entry.Installer.Kind, options); err != nil {
+		return fmt.Errorf("error installing: %w", err)
+	}
+
+	if len(options.Shims) > 0 {
+		if err := createShims(options.Shims, entry.Version); err != nil {
+			return fmt.Errorf("could not create shims: %w", err)
+		}
+	}
+
+	recordInstallState(db, pkg, entry, arch, options)
+
+	return nil
+}
+
+// maybeLoadLockfile loads the lockfile at path, if one was given, returning a Manifest with a nil
+// Packages map (so lookups simply miss) when path is empty.
+func maybeLoadLockfile(path string) (*manifest.Manifest, error) {
+	if isEmptyString(path) {
+		return &manifest.Manifest{}, nil
+	}
+
+	return manifest.Load(path)
+}
+
+// pinEntry returns a copy of entry with its version, architecture-specific checksum, and installer
+// URL short-circuited to the ones recorded in pinned, so it resolves to the exact installer a
+// lockfile captured rather than whatever the registry currently advertises.
+func pinEntry(entry *registry4.Package, pinned manifest.Entry) *registry4.Package {
+	ret := *entry
+	ret.Version = pinned.Version
+
+	if !isEmptyString(pinned.Sha256) {
+		sha256 := map[string]string{pinned.Arch: pinned.Sha256}
+		for k, v := range ret.Installer.Sha256 {
+			if k != pinned.Arch {
+				sha256[k] = v
+			}
+		}
+		ret.Installer.Sha256 = sha256
+	}
+
+	if !isEmptyString(pinned.InstallerURL) {
+		switch pinned.Arch {
+		case "x86":
+			ret.Installer.X86 = pinned.InstallerURL
+		case "x86_64":
+			ret.Installer.X86_64 = pinned.InstallerURL
+		}
+	}
+
+	return &ret
+}
+
+// installPinned runs a package entry, pinned to a lockfile entry's version/arch, through the same
+// fetch/install/shim steps as a regular install.
+func installPinned(db *state.DB, name string, entry *registry4.Package, pinned manifest.Entry, force bool) error {
+	return installOne(db, name, pinEntry(entry, pinned), pinned.Arch, force, false, false)
+}
+
+// recordInstallState tracks what was just installed for pkg in db, so it can later be undone by
+// "just-install uninstall" or superseded by "just-install upgrade".
+func recordInstallState(db *state.DB, pkg string, entry *registry4.Package, arch string, options *registry4.Options) {
+	rec := state.Package{
+		Name:        pkg,
+		Version:     entry.Version,
+		Arch:        arch,
+		Kind:        entry.Installer.Kind,
+		InstalledAt: time.Now(),
+		DisplayName: entry.DisplayName,
+	}
+
+	if options != nil {
+		switch entry.Installer.Kind {
+		case "copy", "zip":
+			if dest, err := expandString(options.Destination, nil); err == nil {
+				rec.Files = []string{dest}
+			}
+		}
+
+		for _, shim := range options.Shims {
+			target, err := expandString(shim.Path, nil)
+			if err != nil {
+				continue
+			}
+
+			shimPath := shimPathFor(target)
+			rec.Shims = append(rec.Shims, shimPath, installer.ShimConfigPath(shimPath))
+		}
+
+		for _, shortcut := range options.Shortcuts {
+			shortcutName, err := expandString(shortcut.Name, nil)
+			if err != nil {
+				continue
+			}
+
+			rec.Shortcuts = append(rec.Shortcuts, filepath.Join(startMenu, shortcutName+".lnk"))
+		}
+	}
+
+	db.Put(rec)
+}
+
 // mustCreateShims calls createShims and aborts when it fails.
-func mustCreateShims(shims []string, entryVersion string) {
+func mustCreateShims(shims []registry4.Shim, entryVersion string) {
 	if err := createShims(shims, entryVersion); err != nil {
 		log.Fatalln(err)
 	}
 }
 
-// createShims tries to create the given shims using exeproxy, if it's installed.
-func createShims(shims []string, entryVersion string) error {
-	exeproxy := os.ExpandEnv("${ProgramFiles(x86)}\\exeproxy\\exeproxy.exe")
-	if !dry.FileExists(exeproxy) {
-		return errors.New("could not find exeproxy")
-	}
-
+// createShims writes out a copy of the embedded shim binary, renamed to each target's base name,
+// next to a "<name>.shim" config file pointing back at the real executable (and its default
+// arguments, if any).
+func createShims(shims []registry4.Shim, entryVersion string) error {
 	if !dry.FileIsDir(shimsPath) {
-		if err := os.MkdirAll(shimsPath, 0); err != nil {
+		if err := os.MkdirAll(shimsPath, os.ModePerm); err != nil {
 			return fmt.Errorf("could not create shims directory: %w", err)
 		}
 	}
 
-	for _, v := range shims {
-		shimTarget, err := expandString(v, nil)
+	for _, shim := range shims {
+		target, err := expandString(shim.Path, nil)
 		if err != nil {
 			return fmt.Errorf("could not expand shim target string: %w", err)
 		}
 
-		shim := filepath.Join(shimsPath, filepath.Base(shimTarget))
-
-		if dry.FileExists(shim) {
-			if err := os.Remove(shim); err != nil {
-				return fmt.Errorf("could not remove existing shim: %v, %w", shim, err)
+		var args []string
+		for _, a := range shim.Args {
+			expanded, err := expandString(a, nil)
+			if err != nil {
+				return fmt.Errorf("could not expand shim argument string: %w", err)
 			}
+
+			args = append(args, expanded)
 		}
 
-		log.Printf("creating shim for %s (%s)\n", shimTarget, shim)
+		shimPath := shimPathFor(target)
+
+		log.Printf("creating shim for %s (%s)\n", target, shimPath)
 
-		if err := cmd.Run(exeproxy, "exeproxy-copy", shim, shimTarget); err != nil {
+		if err := installer.WriteShim(shimPath, target, args); err != nil {
 			return fmt.Errorf("could not create shim: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// shimPathFor returns where the shim binary for the given target executable should live.
+func shimPathFor(target string) string {
+	return filepath.Join(shimsPath, strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))+".exe")
+}
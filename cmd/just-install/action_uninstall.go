@@ -0,0 +1,192 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/just-install/just-install/pkg/cmd"
+	"github.com/just-install/just-install/pkg/state"
+	"github.com/just-install/just-install/pkg/winuninstall"
+)
+
+// handleUninstall removes packages just-install previously installed, using the state database to
+// find what it needs to clean up.
+func handleUninstall(c *cli.Context) error {
+	pkgs := c.Args().Slice()
+	if len(pkgs) < 1 {
+		return errors.New("usage: just-install uninstall <package>...")
+	}
+
+	db, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("could not load state database: %w", err)
+	}
+
+	hasErrors := false
+
+	for _, pkg := range pkgs {
+		rec, ok := db.Packages[pkg]
+		if !ok {
+			log.Println("WARNING: just-install has no record of installing", pkg)
+			continue
+		}
+
+		if err := uninstallPackage(rec); err != nil {
+			log.Printf("error uninstalling %v: %v", pkg, err)
+			hasErrors = true
+			continue
+		}
+
+		db.Remove(pkg)
+	}
+
+	if err := db.Save(); err != nil {
+		log.Printf("could not save state database: %v", err)
+	}
+
+	if hasErrors {
+		return errors.New("encountered errors uninstalling packages (see the log for details)")
+	}
+
+	return nil
+}
+
+// uninstallPackage undoes whatever just-install did to install rec.
+func uninstallPackage(rec state.Package) error {
+	switch rec.Kind {
+	case "copy", "zip":
+		return removeTrackedFiles(rec)
+	default:
+		return runSilentUninstaller(rec)
+	}
+}
+
+// removeTrackedFiles deletes the files, shims and shortcuts that a "copy"/"zip" installer created.
+func removeTrackedFiles(rec state.Package) error {
+	for _, path := range rec.Files {
+		log.Println("removing", path)
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("could not remove %v: %w", path, err)
+		}
+	}
+
+	for _, path := range append(append([]string{}, rec.Shims...), rec.Shortcuts...) {
+		log.Println("removing", path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove %v: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// runSilentUninstaller looks up the real installer's registered uninstall command in the Windows
+// Uninstall registry key and runs it silently.
+func runSilentUninstaller(rec state.Package) error {
+	installed, err := winuninstall.List()
+	if err != nil {
+		return fmt.Errorf("could not enumerate installed programs: %w", err)
+	}
+
+	entry, ok := winuninstall.Find(installed, rec.Name, rec.DisplayName)
+	if !ok {
+		return fmt.Errorf("could not find %v in the Windows Uninstall registry", rec.Name)
+	}
+
+	args, err := silentUninstallArgs(rec.Kind, entry)
+	if err != nil {
+		return err
+	}
+
+	return cmd.Run(args...)
+}
+
+// silentUninstallArgs builds the silent uninstall command line for a package of the given kind,
+// from its Windows Uninstall registry entry.
+func silentUninstallArgs(kind string, entry winuninstall.Entry) ([]string, error) {
+	switch kind {
+	case "msi":
+		if isEmptyString(entry.UninstallString) {
+			return nil, errors.New("no uninstall command registered for this MSI")
+		}
+
+		return append(splitCommandLine(entry.UninstallString), "/quiet", "/norestart"), nil
+	case "innosetup":
+		if !isEmptyString(entry.QuietUninstallString) {
+			return splitCommandLine(entry.QuietUninstallString), nil
+		}
+
+		if isEmptyString(entry.UninstallString) {
+			return nil, errors.New("no uninstall command registered for this Inno Setup package")
+		}
+
+		return append(splitCommandLine(entry.UninstallString), "/VERYSILENT", "/NORESTART"), nil
+	case "nsis":
+		if !isEmptyString(entry.QuietUninstallString) {
+			return splitCommandLine(entry.QuietUninstallString), nil
+		}
+
+		if isEmptyString(entry.UninstallString) {
+			return nil, errors.New("no uninstall command registered for this NSIS package")
+		}
+
+		return append(splitCommandLine(entry.UninstallString), "/S"), nil
+	default:
+		return nil, fmt.Errorf("don't know how to silently uninstall installer kind: %v", kind)
+	}
+}
+
+// splitCommandLine splits a Windows command line (as registered in UninstallString/
+// QuietUninstallString, e.g. `"C:\Program Files\App\unins000.exe" /VERYSILENT`) into argv,
+// keeping a double-quoted substring (typically the executable path) together as one argument.
+// strings.Fields would instead split on every space, mangling any path containing one (which
+// "Program Files" always does).
+func splitCommandLine(s string) []string {
+	var (
+		args     []string
+		current  strings.Builder
+		inQuotes bool
+	)
+
+	flush := func() {
+		if current.Len() > 0 {
+			args = append(args, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return args
+}
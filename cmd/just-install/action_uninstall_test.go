@@ -0,0 +1,48 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommandLine_QuotedPathWithSpaces(t *testing.T) {
+	got := splitCommandLine(`"C:\Program Files\App\unins000.exe" /VERYSILENT /NORESTART`)
+	want := []string{`C:\Program Files\App\unins000.exe`, "/VERYSILENT", "/NORESTART"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSplitCommandLine_UnquotedPathNoSpaces(t *testing.T) {
+	got := splitCommandLine(`C:\App\uninstall.exe /S`)
+	want := []string{`C:\App\uninstall.exe`, "/S"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSplitCommandLine_NoTrailingArgs(t *testing.T) {
+	got := splitCommandLine(`"C:\Program Files\App\unins000.exe"`)
+	want := []string{`C:\Program Files\App\unins000.exe`}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
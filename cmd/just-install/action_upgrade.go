@@ -0,0 +1,87 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/just-install/just-install/pkg/state"
+)
+
+// handleUpgrade reinstalls every package whose registry version no longer matches what state
+// recorded as installed. With no package names given, every tracked package is considered.
+func handleUpgrade(c *cli.Context) error {
+	force := c.Bool("force")
+	skipVerify := c.Bool("insecure-skip-verify")
+
+	registry, err := loadRegistry(c, force)
+	if err != nil {
+		return err
+	}
+
+	db, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("could not load state database: %w", err)
+	}
+
+	pkgs := c.Args().Slice()
+	if len(pkgs) < 1 {
+		for pkg := range db.Packages {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	hasErrors := false
+
+	for _, pkg := range pkgs {
+		rec, ok := db.Packages[pkg]
+		if !ok {
+			log.Println("WARNING: just-install has no record of installing", pkg)
+			continue
+		}
+
+		entry, ok := registry.Packages[pkg]
+		if !ok {
+			log.Println("WARNING: unknown package", pkg)
+			continue
+		}
+
+		if entry.Version == rec.Version {
+			continue
+		}
+
+		log.Printf("upgrading %v: %v -> %v", pkg, rec.Version, entry.Version)
+
+		if err := installOne(db, pkg, entry, rec.Arch, force, skipVerify, false); err != nil {
+			log.Printf("error upgrading %v: %v", pkg, err)
+			hasErrors = true
+		}
+	}
+
+	if err := db.Save(); err != nil {
+		log.Printf("could not save state database: %v", err)
+	}
+
+	if hasErrors {
+		return errors.New("encountered errors upgrading packages (see the log for details)")
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package fetch downloads installers to disk, resuming a partial download with an HTTP Range
+// request when one was left behind by an earlier, interrupted attempt.
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxAttempts bounds how many times Fetch retries a download that fails partway through before
+// giving up; each retry resumes from the ".part" file's current size rather than starting over.
+const maxAttempts = 3
+
+// Options configures a single Fetch call.
+type Options struct {
+	Destination string
+	Overwrite   bool
+
+	// Progress, when non-nil, receives this download's progress alongside that of any other
+	// in-flight Fetch call sharing the same *Progress, so a caller downloading several files
+	// concurrently can log one combined total instead of an independent line per URL.
+	Progress *Progress
+}
+
+// Progress aggregates download progress across every Fetch call that shares it. A single Progress
+// is meant to be created once by a caller (e.g. cmd/just-install's fetchConcurrently) and passed to
+// every concurrent Fetch call in that batch.
+type Progress struct {
+	mu         sync.Mutex
+	downloaded int64
+	total      int64
+	loggedAt   int64
+}
+
+// NewProgress returns a Progress tracker ready to be shared across concurrent Fetch calls.
+func NewProgress() *Progress {
+	return &Progress{}
+}
+
+// addTotal folds a newly started download's content length into the aggregate total. A
+// non-positive total means the server didn't report a content length; once that happens the
+// aggregate total is considered unknown for the rest of the batch, since there's no way to tell
+// how much of it is left to account for.
+func (p *Progress) addTotal(total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.total < 0 {
+		return
+	}
+
+	if total <= 0 {
+		p.total = -1
+		return
+	}
+
+	p.total += total
+}
+
+// add records n more downloaded bytes and, at most once per progressLogInterval, logs the
+// aggregate progress across every download sharing this Progress.
+func (p *Progress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.downloaded += n
+	if p.downloaded-p.loggedAt < progressLogInterval {
+		return
+	}
+
+	p.loggedAt = p.downloaded
+
+	if p.total > 0 {
+		log.Printf("downloading: %d/%d bytes", p.downloaded, p.total)
+	} else {
+		log.Printf("downloading: %d bytes", p.downloaded)
+	}
+}
+
+// Fetch downloads u into Options.Destination, resuming a previous partial download when the
+// server supports it, and returns the path to the downloaded file.
+func Fetch(u string, opts *Options) (string, error) {
+	destPath, err := destinationPath(u, opts.Destination)
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.Overwrite {
+		if _, err := os.Stat(destPath); err == nil {
+			return destPath, nil
+		}
+	}
+
+	partPath := destPath + ".part"
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if lastErr != nil {
+			log.Printf("retrying download of %v (attempt %d/%d) after: %v", u, attempt, maxAttempts, lastErr)
+		}
+
+		if lastErr = fetchOnce(u, partPath, opts.Progress); lastErr == nil {
+			if err := os.Rename(partPath, destPath); err != nil {
+				return "", err
+			}
+
+			return destPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not download %v after %d attempts: %w", u, maxAttempts, lastErr)
+}
+
+// fetchOnce performs a single download attempt, continuing from partPath's current size via an
+// HTTP Range request if the file already exists and the server honors it.
+func fetchOnce(u, partPath string, progress *Progress) error {
+	var offset int64
+	if fi, err := os.Stat(partPath); err == nil {
+		offset = fi.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server ignored our Range request (or this is the first attempt); start over.
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	default:
+		return fmt.Errorf("unexpected status fetching %v: %v", u, resp.Status)
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	if progress != nil {
+		progress.addTotal(resp.ContentLength)
+		w = io.MultiWriter(f, &progressWriter{progress: progress})
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// destinationPath returns the file u should be downloaded to under dir, taking the last path
+// segment of u (ignoring any query string) as the file name.
+func destinationPath(u, dir string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("could not parse download URL: %w", err)
+	}
+
+	return filepath.Join(dir, filepath.Base(parsed.Path)), nil
+}
+
+// progressLogInterval is how often, in aggregate bytes downloaded, a Progress logs a progress
+// line. Logging on every Write would otherwise produce a line per TCP read.
+const progressLogInterval = 1 << 20 // 1 MiB
+
+// progressWriter forwards the bytes written for a single download into the Progress shared across
+// its whole batch.
+type progressWriter struct {
+	progress *Progress
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.progress.add(int64(len(b)))
+	return len(b), nil
+}
@@ -0,0 +1,64 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package fetch
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestProgress_AddTotalAggregatesAcrossWorkers(t *testing.T) {
+	p := NewProgress()
+	p.addTotal(100)
+	p.addTotal(200)
+
+	if p.total != 300 {
+		t.Fatalf("got total %d, want 300", p.total)
+	}
+}
+
+func TestProgress_AddTotalUnknownPoisonsTheAggregate(t *testing.T) {
+	p := NewProgress()
+	p.addTotal(100)
+	p.addTotal(0) // one worker's server didn't report a content length
+
+	if p.total != -1 {
+		t.Fatalf("got total %d, want -1 (unknown)", p.total)
+	}
+
+	p.addTotal(50)
+	if p.total != -1 {
+		t.Fatalf("got total %d, want -1 to stay unknown", p.total)
+	}
+}
+
+func TestProgress_AddIsSafeForConcurrentWorkers(t *testing.T) {
+	p := NewProgress()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.add(1 << 16)
+		}()
+	}
+	wg.Wait()
+
+	if p.downloaded != 10*(1<<16) {
+		t.Fatalf("got downloaded %d, want %d", p.downloaded, 10*(1<<16))
+	}
+}
@@ -0,0 +1,140 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/just-install/just-install/pkg/registry4"
+)
+
+// chocolateyNuspec is the subset of a Chocolatey package's .nuspec just-install cares about.
+type chocolateyNuspec struct {
+	Metadata struct {
+		Version string `xml:"version"`
+	} `xml:"metadata"`
+}
+
+// chocolateyInstallVar matches a PowerShell variable assignment of the form `$name = 'value'` (or
+// double-quoted), as used by the Install-ChocolateyPackage/Install-ChocolateyZipPackage helpers
+// that chocolateyInstall.ps1 scripts call into.
+var chocolateyInstallVar = regexp.MustCompile(`(?im)^\s*\$(\w+)\s*=\s*['"]([^'"]*)['"]`)
+
+// chocolateyAdapter imports a Chocolatey package from a local package directory, i.e. one
+// containing a "*.nuspec" file and a "tools/chocolateyInstall.ps1" script.
+type chocolateyAdapter struct{}
+
+func (chocolateyAdapter) Import(source string) (*registry4.Package, error) {
+	nuspecPath, err := findNuspec(source)
+	if err != nil {
+		return nil, err
+	}
+
+	nuspecBytes, err := ioutil.ReadFile(nuspecPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var nuspec chocolateyNuspec
+	if err := xml.Unmarshal(nuspecBytes, &nuspec); err != nil {
+		return nil, fmt.Errorf("could not parse nuspec: %w", err)
+	}
+
+	installScript, err := ioutil.ReadFile(filepath.Join(source, "tools", "chocolateyInstall.ps1"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read chocolateyInstall.ps1: %w", err)
+	}
+
+	vars := parseInstallVars(string(installScript))
+
+	pkg := &registry4.Package{
+		Version: nuspec.Metadata.Version,
+	}
+	pkg.Installer.X86 = vars["url"]
+	pkg.Installer.X86_64 = vars["url64"]
+	pkg.Installer.Kind = chocolateyKind(installScript, vars["silentArgs"])
+
+	sha256 := map[string]string{}
+	if vars["checksum"] != "" {
+		sha256["x86"] = strings.ToLower(vars["checksum"])
+	}
+	if vars["checksum64"] != "" {
+		sha256["x86_64"] = strings.ToLower(vars["checksum64"])
+	}
+	if len(sha256) > 0 {
+		pkg.Installer.Sha256 = sha256
+	}
+
+	// The "custom" kind is the only one that consults Options.Arguments, so it's the only one
+	// silentArgs is worth carrying over for; installers of a known kind (zip, msi) get their
+	// silent flags from pkg/installer itself.
+	if pkg.Installer.Kind == "custom" {
+		args := []string{"{{.installer}}"}
+		args = append(args, strings.Fields(vars["silentArgs"])...)
+		pkg.Installer.Options = &registry4.Options{Arguments: args}
+	}
+
+	return pkg, nil
+}
+
+// findNuspec locates the single *.nuspec file inside a Chocolatey package directory.
+func findNuspec(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.nuspec"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(matches) != 1 {
+		return "", fmt.Errorf("expected exactly one .nuspec file in %v, found %v", dir, len(matches))
+	}
+
+	return matches[0], nil
+}
+
+// parseInstallVars extracts the PowerShell variable assignments chocolateyInstall.ps1 commonly
+// feeds into Install-ChocolateyPackage/Install-ChocolateyZipPackage.
+func parseInstallVars(script string) map[string]string {
+	ret := map[string]string{}
+
+	for _, m := range chocolateyInstallVar.FindAllStringSubmatch(script, -1) {
+		ret[m[1]] = m[2]
+	}
+
+	return ret
+}
+
+// chocolateyKind guesses the installer kind from which Chocolatey helper the install script calls.
+// A script that drives a generic executable installer with its own silentArgs (rather than calling
+// Install-ChocolateyZipPackage or an .msi helper) has no just-install kind that understands those
+// arguments except "custom", so that's what's returned for it, mirroring the equivalent WinGet
+// exe/burn mapping.
+func chocolateyKind(script []byte, silentArgs string) string {
+	switch {
+	case strings.Contains(string(script), "Install-ChocolateyZipPackage"):
+		return "zip"
+	case strings.Contains(string(script), ".msi"):
+		return "msi"
+	case silentArgs != "":
+		return "custom"
+	default:
+		return "nsis"
+	}
+}
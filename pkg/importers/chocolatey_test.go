@@ -0,0 +1,44 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import "testing"
+
+func TestChocolateyKind_Zip(t *testing.T) {
+	if got := chocolateyKind([]byte("Install-ChocolateyZipPackage @args"), ""); got != "zip" {
+		t.Fatalf("got %v, want zip", got)
+	}
+}
+
+func TestChocolateyKind_Msi(t *testing.T) {
+	if got := chocolateyKind([]byte("$url = 'https://example.com/app.msi'"), ""); got != "msi" {
+		t.Fatalf("got %v, want msi", got)
+	}
+}
+
+func TestChocolateyKind_GenericExeWithSilentArgsIsCustom(t *testing.T) {
+	got := chocolateyKind([]byte("Install-ChocolateyPackage @args"), "/VERYSILENT /NORESTART")
+	if got != "custom" {
+		t.Fatalf("got %v, want custom", got)
+	}
+}
+
+func TestChocolateyKind_GenericExeWithoutSilentArgsFallsBackToNsis(t *testing.T) {
+	got := chocolateyKind([]byte("Install-ChocolateyPackage @args"), "")
+	if got != "nsis" {
+		t.Fatalf("got %v, want nsis", got)
+	}
+}
@@ -0,0 +1,64 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package importers converts package definitions from external community registries (WinGet,
+// Chocolatey, Scoop) into registry4.Package values, so a maintainer can grow just-install's own
+// registry without hand-writing every JSON stanza.
+package importers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/just-install/just-install/pkg/fetch"
+	"github.com/just-install/just-install/pkg/paths"
+	"github.com/just-install/just-install/pkg/registry4"
+)
+
+// Adapter converts a single community-registry manifest, identified by source, into a
+// registry4.Package.
+type Adapter interface {
+	// Import resolves source - a URL or local file/directory path, depending on the adapter - to
+	// a registry4.Package.
+	Import(source string) (*registry4.Package, error)
+}
+
+// Adapters maps a "--source" name to the adapter that handles it.
+var Adapters = map[string]Adapter{
+	"winget":     wingetAdapter{},
+	"chocolatey": chocolateyAdapter{},
+	"scoop":      scoopAdapter{},
+}
+
+// readSource returns the content at source, fetching it if it looks like a URL and reading it
+// from disk otherwise.
+func readSource(source string) ([]byte, error) {
+	if !strings.Contains(source, "://") {
+		return ioutil.ReadFile(source)
+	}
+
+	dir, err := paths.TempDirCreate()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := fetch.Fetch(source, &fetch.Options{Destination: dir, Overwrite: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch manifest: %w", err)
+	}
+
+	return ioutil.ReadFile(path)
+}
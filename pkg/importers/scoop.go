@@ -0,0 +1,136 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/just-install/just-install/pkg/registry4"
+)
+
+// scoopManifest is the subset of a Scoop bucket manifest just-install cares about. See
+// https://github.com/ScoopInstaller/Scoop/wiki/App-Manifests.
+type scoopManifest struct {
+	Version     string `json:"version"`
+	URL         string `json:"url"`
+	Hash        string `json:"hash"`
+	InnoSetup   bool   `json:"innosetup"`
+	Msi         string `json:"msi"`
+	Uninstaller struct {
+		Args []string `json:"args"`
+	} `json:"uninstaller"`
+
+	Architecture struct {
+		Bit64 struct {
+			URL  string `json:"url"`
+			Hash string `json:"hash"`
+		} `json:"64bit"`
+		Bit32 struct {
+			URL  string `json:"url"`
+			Hash string `json:"hash"`
+		} `json:"32bit"`
+	} `json:"architecture"`
+}
+
+// scoopAdapter imports a Scoop bucket manifest (a JSON file, fetched from a URL or read from
+// disk).
+type scoopAdapter struct{}
+
+func (scoopAdapter) Import(source string) (*registry4.Package, error) {
+	b, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var m scoopManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse scoop manifest: %w", err)
+	}
+
+	pkg := &registry4.Package{Version: m.Version}
+
+	x86URL, x86Hash := m.URL, m.Hash
+	x64URL, x64Hash := m.Architecture.Bit64.URL, m.Architecture.Bit64.Hash
+
+	if m.Architecture.Bit32.URL != "" {
+		x86URL, x86Hash = m.Architecture.Bit32.URL, m.Architecture.Bit32.Hash
+	}
+	if x64URL == "" {
+		x64URL, x64Hash = x86URL, x86Hash
+	}
+
+	pkg.Installer.X86 = x86URL
+	pkg.Installer.X86_64 = x64URL
+
+	sha256 := map[string]string{}
+	if x86Hash != "" {
+		h, err := scoopSha256(x86Hash)
+		if err != nil {
+			return nil, fmt.Errorf("32-bit installer: %w", err)
+		}
+		sha256["x86"] = h
+	}
+	if x64Hash != "" {
+		h, err := scoopSha256(x64Hash)
+		if err != nil {
+			return nil, fmt.Errorf("64-bit installer: %w", err)
+		}
+		sha256["x86_64"] = h
+	}
+	if len(sha256) > 0 {
+		pkg.Installer.Sha256 = sha256
+	}
+
+	switch {
+	case m.InnoSetup:
+		pkg.Installer.Kind = "innosetup"
+	case m.Msi != "":
+		pkg.Installer.Kind = "msi"
+	default:
+		pkg.Installer.Kind = "zip"
+	}
+
+	return pkg, nil
+}
+
+// scoopSha256 normalizes a Scoop manifest hash to the lowercase hex digest just-install's Sha256
+// checksum expects. Scoop hashes are optionally prefixed with "<algo>:" (e.g. "sha1:..." on older
+// manifests still using the legacy algorithm); a bare hash is assumed to be SHA-256, Scoop's
+// documented default. Anything that isn't actually a 64-character SHA-256 hex digest is rejected,
+// rather than silently importing a checksum that verify.File could never match.
+func scoopSha256(hash string) (string, error) {
+	algo, digest := "sha256", hash
+	if i := strings.Index(hash, ":"); i >= 0 {
+		algo, digest = strings.ToLower(hash[:i]), hash[i+1:]
+	}
+
+	if algo != "sha256" {
+		return "", fmt.Errorf("unsupported hash algorithm %q (only sha256 is supported)", algo)
+	}
+
+	digest = strings.ToLower(digest)
+	if len(digest) != 64 || strings.IndexFunc(digest, isNotHexDigit) >= 0 {
+		return "", fmt.Errorf("%q is not a valid sha256 hex digest", hash)
+	}
+
+	return digest, nil
+}
+
+func isNotHexDigit(r rune) bool {
+	return !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f')
+}
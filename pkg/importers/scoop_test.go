@@ -0,0 +1,67 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import "testing"
+
+func TestScoopSha256_Bare(t *testing.T) {
+	digest := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcd"
+
+	got, err := scoopSha256(digest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != digest {
+		t.Fatalf("got %v, want %v", got, digest)
+	}
+}
+
+func TestScoopSha256_UppercaseIsLowered(t *testing.T) {
+	got, err := scoopSha256("0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcd" {
+		t.Fatalf("got %v, want lowercase digest", got)
+	}
+}
+
+func TestScoopSha256_PrefixedSha256(t *testing.T) {
+	digest := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcd"
+
+	got, err := scoopSha256("sha256:" + digest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != digest {
+		t.Fatalf("got %v, want %v", got, digest)
+	}
+}
+
+func TestScoopSha256_RejectsLegacyAlgorithm(t *testing.T) {
+	if _, err := scoopSha256("sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709"); err == nil {
+		t.Fatal("expected an error for a non-sha256 hash, got nil")
+	}
+}
+
+func TestScoopSha256_RejectsWrongLength(t *testing.T) {
+	if _, err := scoopSha256("deadbeef"); err == nil {
+		t.Fatal("expected an error for a malformed hash, got nil")
+	}
+}
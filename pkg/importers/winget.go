@@ -0,0 +1,130 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/just-install/just-install/pkg/registry4"
+)
+
+// wingetManifest is the subset of a WinGet installer manifest just-install cares about. See
+// https://github.com/microsoft/winget-pkgs/blob/master/doc/manifest/schema/1.6.0/installer.md.
+type wingetManifest struct {
+	PackageVersion string `yaml:"PackageVersion"`
+
+	Installers []struct {
+		Architecture      string `yaml:"Architecture"`
+		InstallerURL      string `yaml:"InstallerUrl"`
+		InstallerSha256   string `yaml:"InstallerSha256"`
+		InstallerType     string `yaml:"InstallerType"`
+		InstallerSwitches struct {
+			Silent string `yaml:"Silent"`
+		} `yaml:"InstallerSwitches"`
+	} `yaml:"Installers"`
+}
+
+// wingetAdapter imports a WinGet installer manifest (a YAML file, fetched from a URL or read from
+// disk).
+type wingetAdapter struct{}
+
+func (wingetAdapter) Import(source string) (*registry4.Package, error) {
+	b, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var m wingetManifest
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("could not parse winget manifest: %w", err)
+	}
+
+	pkg := &registry4.Package{Version: m.PackageVersion}
+	sha256 := map[string]string{}
+
+	for _, inst := range m.Installers {
+		arch := wingetArch(inst.Architecture)
+		if arch == "" {
+			continue
+		}
+
+		switch arch {
+		case "x86":
+			pkg.Installer.X86 = inst.InstallerURL
+		case "x86_64":
+			pkg.Installer.X86_64 = inst.InstallerURL
+		}
+
+		if inst.InstallerSha256 != "" {
+			sha256[arch] = strings.ToLower(inst.InstallerSha256)
+		}
+
+		if pkg.Installer.Kind == "" {
+			pkg.Installer.Kind = wingetKind(inst.InstallerType)
+
+			// The "custom" kind is the only one that consults Options.Arguments, so it's the
+			// only one whose Silent switches are worth carrying over; installers of a known
+			// kind (msi, nsis, ...) get their silent flags from pkg/installer itself.
+			if pkg.Installer.Kind == "custom" {
+				args := []string{"{{.installer}}"}
+				args = append(args, strings.Fields(inst.InstallerSwitches.Silent)...)
+				pkg.Installer.Options = &registry4.Options{Arguments: args}
+			}
+		}
+	}
+
+	if len(sha256) > 0 {
+		pkg.Installer.Sha256 = sha256
+	}
+
+	return pkg, nil
+}
+
+// wingetArch maps a WinGet Architecture value to the architecture keys used throughout
+// just-install's registry.
+func wingetArch(arch string) string {
+	switch strings.ToLower(arch) {
+	case "x86":
+		return "x86"
+	case "x64":
+		return "x86_64"
+	default:
+		return ""
+	}
+}
+
+// wingetKind maps a WinGet InstallerType to the installer kind just-install understands. WinGet's
+// "exe"/"burn" types (the ones that carry InstallerSwitches.Silent) have no just-install equivalent,
+// so they're mapped to "custom" and driven through Options.Arguments instead.
+func wingetKind(installerType string) string {
+	switch strings.ToLower(installerType) {
+	case "msi", "wix":
+		return "msi"
+	case "inno":
+		return "innosetup"
+	case "nullsoft":
+		return "nsis"
+	case "zip":
+		return "zip"
+	case "exe", "burn":
+		return "custom"
+	default:
+		return strings.ToLower(installerType)
+	}
+}
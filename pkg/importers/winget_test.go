@@ -0,0 +1,42 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package importers
+
+import "testing"
+
+func TestWingetKind_KnownTypesPassThrough(t *testing.T) {
+	cases := map[string]string{
+		"msi":      "msi",
+		"Wix":      "msi",
+		"Inno":     "innosetup",
+		"Nullsoft": "nsis",
+		"ZIP":      "zip",
+	}
+
+	for in, want := range cases {
+		if got := wingetKind(in); got != want {
+			t.Errorf("wingetKind(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestWingetKind_ExeAndBurnMapToCustom(t *testing.T) {
+	for _, in := range []string{"exe", "Exe", "burn", "Burn"} {
+		if got := wingetKind(in); got != "custom" {
+			t.Errorf("wingetKind(%q) = %v, want custom", in, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Command shim is the tiny binary just-install embeds and copies out for every shim it creates.
+// Run as "<name>.exe", it reads the "path"/"args" directives out of the sibling "<name>.shim"
+// config file installer.WriteShim wrote next to it, then execs the real target with its default
+// arguments followed by whatever arguments the caller passed to the shim.
+//
+// This is built out-of-tree (see the go:generate directive in ../../shim.go) and the resulting
+// binary is what gets embedded via go:embed; it intentionally has no dependencies outside the
+// standard library so it can be cross-compiled for Windows without a network connection.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "shim:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	target, defaultArgs, err := readConfig(configPath(self))
+	if err != nil {
+		return err
+	}
+
+	args := append(defaultArgs, os.Args[1:]...)
+
+	cmd := exec.Command(target, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// configPath returns the "<name>.shim" config file that accompanies the shim binary at
+// shimPath, mirroring installer.ShimConfigPath.
+func configPath(shimPath string) string {
+	return strings.TrimSuffix(shimPath, ".exe") + ".shim"
+}
+
+// readConfig parses a "key = value" shim config file, returning the target executable's path and
+// its default arguments (split on whitespace).
+func readConfig(path string) (string, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	var target string
+	var args []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "path":
+			target = value
+		case "args":
+			if value != "" {
+				args = strings.Fields(value)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", nil, err
+	}
+
+	if target == "" {
+		return "", nil, fmt.Errorf("%v: missing \"path\" directive", path)
+	}
+
+	return target, args, nil
+}
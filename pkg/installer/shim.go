@@ -0,0 +1,55 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package installer
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+//go:generate env GOOS=windows GOARCH=amd64 CGO_ENABLED=0 go build -trimpath -ldflags=-s\ -w -o assets/shim.exe ./cmd/shim
+
+//go:embed assets/shim.exe
+var shimBinary []byte
+
+// WriteShim drops a copy of the embedded shim binary at shimPath plus a "<shimPath>.shim" config
+// file recording the real executable (and, optionally, default arguments) it should exec.
+func WriteShim(shimPath, target string, args []string) error {
+	if err := ioutil.WriteFile(shimPath, shimBinary, 0755); err != nil {
+		return fmt.Errorf("could not write shim binary: %w", err)
+	}
+
+	var config strings.Builder
+	fmt.Fprintf(&config, "path = %s\n", target)
+
+	if len(args) > 0 {
+		fmt.Fprintf(&config, "args = %s\n", strings.Join(args, " "))
+	}
+
+	if err := ioutil.WriteFile(ShimConfigPath(shimPath), []byte(config.String()), 0644); err != nil {
+		return fmt.Errorf("could not write shim config: %w", err)
+	}
+
+	return nil
+}
+
+// ShimConfigPath returns the path of the ".shim" config file that accompanies the shim binary at
+// shimPath.
+func ShimConfigPath(shimPath string) string {
+	return strings.TrimSuffix(shimPath, ".exe") + ".shim"
+}
@@ -0,0 +1,92 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package manifest reads and writes just-install.lock.json(/.yaml) files: a pinned set of
+// package name, version, architecture and installer URL/hash, captured from a registry at
+// install time so a machine's package set can be reproduced elsewhere.
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the top level document of a lockfile.
+type Manifest struct {
+	Packages map[string]Entry `json:"packages" yaml:"packages"`
+}
+
+// Entry pins a single package to the exact version/arch/installer that was resolved for it.
+type Entry struct {
+	Version      string `json:"version" yaml:"version"`
+	Arch         string `json:"arch" yaml:"arch"`
+	InstallerURL string `json:"installerUrl" yaml:"installerUrl"`
+	Sha256       string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+}
+
+// Load reads a lockfile at the given path, picking JSON or YAML decoding based on its extension.
+func Load(path string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Manifest{}
+	if isYAML(path) {
+		err = yaml.Unmarshal(b, ret)
+	} else {
+		err = json.Unmarshal(b, ret)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Save writes the manifest to the given path, picking JSON or YAML encoding based on its
+// extension.
+func Save(path string, m *Manifest) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	if isYAML(path) {
+		b, err = yaml.Marshal(m)
+	} else {
+		b, err = json.MarshalIndent(m, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package registry4
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/just-install/just-install/pkg/strings2"
+)
+
+// Registry is the top level document of a registry4 JSON file.
+type Registry struct {
+	Packages PackageMap `json:"packages"`
+}
+
+// PackageMap maps a package name to its entry.
+type PackageMap map[string]*Package
+
+// Package is a single entry in the registry.
+type Package struct {
+	Version   string    `json:"version"`
+	Installer Installer `json:"installer"`
+
+	// DisplayName optionally names the exact "DisplayName" this package registers itself under in
+	// the Windows Uninstall registry key, for packages where that differs from the package's own
+	// registry key (the common case). "freeze" and "uninstall" use it, falling back to the
+	// package's key, to find the program's Uninstall entry by exact match.
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// Installer describes how to fetch and run the installer for a package.
+type Installer struct {
+	X86         string `json:"x86,omitempty"`
+	X86_64      string `json:"x86_64,omitempty"`
+	Kind        string `json:"type"`
+	Interactive bool   `json:"interactive,omitempty"`
+
+	// Sha256 and Sha512 map an architecture ("x86" or "x86_64") to the expected hex-encoded
+	// digest of the installer downloaded for that architecture. Either, both, or neither may be
+	// set; when neither is set no checksum verification is performed.
+	Sha256 map[string]string `json:"sha256,omitempty"`
+	Sha512 map[string]string `json:"sha512,omitempty"`
+
+	// Signature optionally describes a detached signature covering the installer.
+	Signature *Signature `json:"signature,omitempty"`
+
+	Options *Options `json:"options,omitempty"`
+}
+
+// Signature describes a detached signature that can be checked against a downloaded installer.
+type Signature struct {
+	// Kind selects the verification backend, e.g. "cosign" or "minisign".
+	Kind string `json:"type"`
+
+	// PublicKey is the cosign/minisign public key (or path/KMS reference) used to verify URL.
+	PublicKey string `json:"publicKey"`
+
+	// URL maps an architecture to the location of the detached signature for that architecture's
+	// installer.
+	URL map[string]string `json:"url"`
+}
+
+// Options carries additional, installer-kind-specific, configuration for a package.
+type Options struct {
+	Destination string     `json:"destination,omitempty"`
+	Arguments   []string   `json:"arguments,omitempty"`
+	Shims       []Shim     `json:"shims,omitempty"`
+	Container   *Container `json:"container,omitempty"`
+	Shortcuts   []Shortcut `json:"shortcuts,omitempty"`
+}
+
+// Shim describes a shim to create for an executable dropped by a "copy"/"zip" installer, so it
+// can be invoked from anywhere on the PATH.
+type Shim struct {
+	Path string   `json:"path"`
+	Args []string `json:"args,omitempty"`
+}
+
+// UnmarshalJSON accepts either the pre-Args plain string form ("shims": ["foo.exe"]) still found
+// in older registry files, or the current {"path": ..., "args": [...]} object form.
+func (s *Shim) UnmarshalJSON(b []byte) error {
+	var path string
+	if err := json.Unmarshal(b, &path); err == nil {
+		s.Path = path
+		s.Args = nil
+		return nil
+	}
+
+	type shimAlias Shim // avoid recursing back into this method
+
+	var alias shimAlias
+	if err := json.Unmarshal(b, &alias); err != nil {
+		return err
+	}
+
+	*s = Shim(alias)
+
+	return nil
+}
+
+// Container describes an archive that must be extracted before the real installer can be run.
+type Container struct {
+	Kind      string `json:"type"`
+	Installer string `json:"installer"`
+}
+
+// Shortcut describes a Start Menu shortcut to create after a "zip" installer has been extracted.
+type Shortcut struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+}
+
+// OptionsForArch returns the options applicable when installing this installer for the given
+// architecture, failing if the installer doesn't support it.
+func (i Installer) OptionsForArch(arch string) (*Options, error) {
+	switch arch {
+	case "x86":
+		if strings2.IsEmpty(i.X86) {
+			return nil, errors.New("this package doesn't offer a 32-bit installer")
+		}
+	case "x86_64":
+		if strings2.IsEmpty(i.X86_64) && strings2.IsEmpty(i.X86) {
+			return nil, errors.New("package entry is missing both 32-bit and 64-bit installers")
+		}
+	default:
+		return nil, errors.New("unknown architecture")
+	}
+
+	return i.Options, nil
+}
+
+// ChecksumFor returns the expected SHA-256 and SHA-512 digests (either of which may be empty) for
+// the installer targeting the given architecture.
+func (i Installer) ChecksumFor(arch string) (sha256, sha512 string) {
+	return i.Sha256[arch], i.Sha512[arch]
+}
+
+// SignatureURLFor returns the detached signature URL for the given architecture, or the empty
+// string if this installer doesn't declare one.
+func (i Installer) SignatureURLFor(arch string) string {
+	if i.Signature == nil {
+		return ""
+	}
+
+	return i.Signature.URL[arch]
+}
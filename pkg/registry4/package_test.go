@@ -0,0 +1,62 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package registry4
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestShimUnmarshalJSON_PlainString(t *testing.T) {
+	var s Shim
+	if err := json.Unmarshal([]byte(`"foo.exe"`), &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Path != "foo.exe" || len(s.Args) != 0 {
+		t.Fatalf("got %+v, want {Path: foo.exe}", s)
+	}
+}
+
+func TestShimUnmarshalJSON_Object(t *testing.T) {
+	var s Shim
+	if err := json.Unmarshal([]byte(`{"path": "foo.exe", "args": ["--flag"]}`), &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s.Path != "foo.exe" || len(s.Args) != 1 || s.Args[0] != "--flag" {
+		t.Fatalf("got %+v, want {Path: foo.exe, Args: [--flag]}", s)
+	}
+}
+
+func TestOptionsUnmarshalJSON_MixedShims(t *testing.T) {
+	var opts Options
+	if err := json.Unmarshal([]byte(`{"shims": ["old.exe", {"path": "new.exe", "args": ["-q"]}]}`), &opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(opts.Shims) != 2 {
+		t.Fatalf("got %d shims, want 2", len(opts.Shims))
+	}
+
+	if opts.Shims[0].Path != "old.exe" || len(opts.Shims[0].Args) != 0 {
+		t.Fatalf("got %+v, want {Path: old.exe}", opts.Shims[0])
+	}
+
+	if opts.Shims[1].Path != "new.exe" || len(opts.Shims[1].Args) != 1 {
+		t.Fatalf("got %+v, want {Path: new.exe, Args: [-q]}", opts.Shims[1])
+	}
+}
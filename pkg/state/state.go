@@ -0,0 +1,101 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package state tracks what just-install has installed on a machine, so it can later be
+// uninstalled or upgraded without the user having to remember what went where.
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// path is the location of the state database, mirroring how other per-machine just-install state
+// (e.g. shims) lives under well-known Windows directories rather than next to the binary.
+var path = os.ExpandEnv("${ProgramData}\\just-install\\state.json")
+
+// Package records everything just-install did to install a single package, so it can be undone.
+type Package struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Arch        string    `json:"arch"`
+	Kind        string    `json:"kind"`
+	InstalledAt time.Time `json:"installedAt"`
+
+	// DisplayName is the registry4.Package.DisplayName recorded at install time (which may be
+	// empty), carried over so "uninstall" can look the program up in the Windows Uninstall
+	// registry by the same identity "freeze" would, without needing to reload the registry.
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Files, Shims and Shortcuts are only populated for "copy"/"zip" installers, which just-install
+	// places on disk itself rather than handing off to a real installer that registers itself with
+	// Windows.
+	Files     []string `json:"files,omitempty"`
+	Shims     []string `json:"shims,omitempty"`
+	Shortcuts []string `json:"shortcuts,omitempty"`
+}
+
+// DB is the full set of packages just-install knows it installed.
+type DB struct {
+	Packages map[string]Package `json:"packages"`
+}
+
+// Load reads the state database, returning an empty one if it doesn't exist yet.
+func Load() (*DB, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &DB{Packages: map[string]Package{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ret := &DB{}
+	if err := json.Unmarshal(b, ret); err != nil {
+		return nil, err
+	}
+
+	if ret.Packages == nil {
+		ret.Packages = map[string]Package{}
+	}
+
+	return ret, nil
+}
+
+// Save persists the state database, creating its parent directory if necessary.
+func (db *DB) Save() error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// Put records (or replaces) the tracked state for a package.
+func (db *DB) Put(pkg Package) {
+	db.Packages[pkg.Name] = pkg
+}
+
+// Remove forgets a package, e.g. once it has been uninstalled.
+func (db *DB) Remove(name string) {
+	delete(db.Packages, name)
+}
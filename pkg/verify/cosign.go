@@ -0,0 +1,47 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package verify
+
+import (
+	"fmt"
+
+	"github.com/just-install/just-install/pkg/cmd"
+	"github.com/just-install/just-install/pkg/fetch"
+	"github.com/just-install/just-install/pkg/paths"
+)
+
+// cosignBackend verifies a Sigstore cosign blob signature against a public key.
+type cosignBackend struct{}
+
+func (cosignBackend) verify(filePath string, sig Signature) error {
+	sigPath, err := downloadSignatureFile(sig.URL)
+	if err != nil {
+		return fmt.Errorf("could not download cosign signature: %w", err)
+	}
+
+	return cmd.Run("cosign", "verify-blob", "--key", sig.PublicKey, "--signature", sigPath, filePath)
+}
+
+// downloadSignatureFile fetches the detached signature referenced by url into a temporary
+// directory so it can be handed to the verification backend's CLI.
+func downloadSignatureFile(url string) (string, error) {
+	dir, err := paths.TempDirCreate()
+	if err != nil {
+		return "", err
+	}
+
+	return fetch.Fetch(url, &fetch.Options{Destination: dir, Overwrite: true})
+}
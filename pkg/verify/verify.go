@@ -0,0 +1,114 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package verify checks a downloaded installer against the checksum and/or detached signature
+// declared for it in the registry.
+package verify
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/just-install/just-install/pkg/strings2"
+)
+
+// Options carries the expected checksum and/or signature for a single downloaded file. Any
+// combination of Sha256, Sha512 and Signature may be set; fields left empty/nil are skipped.
+type Options struct {
+	Sha256    string
+	Sha512    string
+	Signature *Signature
+}
+
+// Signature describes a detached signature to check against a file, already resolved to the
+// concrete URL/key for the architecture being installed.
+type Signature struct {
+	// Kind selects the verification backend, e.g. "cosign" or "minisign".
+	Kind string
+
+	// PublicKey is the cosign/minisign public key (or path/KMS reference) to verify against.
+	PublicKey string
+
+	// URL is the location of the detached signature file.
+	URL string
+}
+
+// backend verifies a detached signature against a file. Registry maintainers pick one per
+// package via Signature.Kind.
+type backend interface {
+	verify(filePath string, sig Signature) error
+}
+
+var backends = map[string]backend{
+	"cosign":   cosignBackend{},
+	"minisign": minisignBackend{},
+}
+
+// File verifies the file at path against opts, returning an error describing the first mismatch
+// encountered. A zero-value Options is a no-op: packages that declare no checksum or signature
+// are considered verified.
+func File(path string, opts Options) error {
+	if !strings2.IsEmpty(opts.Sha256) {
+		if err := verifyDigest(path, sha256.New(), opts.Sha256); err != nil {
+			return fmt.Errorf("sha256 verification failed: %w", err)
+		}
+	}
+
+	if !strings2.IsEmpty(opts.Sha512) {
+		if err := verifyDigest(path, sha512.New(), opts.Sha512); err != nil {
+			return fmt.Errorf("sha512 verification failed: %w", err)
+		}
+	}
+
+	if opts.Signature != nil {
+		b, ok := backends[opts.Signature.Kind]
+		if !ok {
+			return fmt.Errorf("unknown signature backend: %v", opts.Signature.Kind)
+		}
+
+		if err := b.verify(path, *opts.Signature); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// verifyDigest hashes the file at path with h and compares it against the expected hex-encoded
+// digest.
+func verifyDigest(path string, h hash.Hash, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("digest mismatch: expected %v, got %v", expected, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package verify
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const verifyTestContent = "just-install"
+
+func writeVerifyTestFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "installer.bin")
+	if err := os.WriteFile(path, []byte(verifyTestContent), 0644); err != nil {
+		t.Fatalf("could not write temp file: %v", err)
+	}
+
+	return path
+}
+
+func TestVerifyDigest_Match(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	sum := sha256.Sum256([]byte(verifyTestContent))
+	expected := hex.EncodeToString(sum[:])
+
+	if err := verifyDigest(path, sha256.New(), expected); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestVerifyDigest_Mismatch(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	err := verifyDigest(path, sha256.New(), strings.Repeat("0", 64))
+	if err == nil {
+		t.Fatal("expected a digest mismatch error, got nil")
+	}
+}
+
+func TestVerifyDigest_IsCaseInsensitive(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	sum := sha256.Sum256([]byte(verifyTestContent))
+	expected := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if err := verifyDigest(path, sha256.New(), expected); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFile_NoOpWithEmptyOptions(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	if err := File(path, Options{}); err != nil {
+		t.Fatalf("unexpected error for a package declaring no checksum/signature: %v", err)
+	}
+}
+
+func TestFile_Sha256Match(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	sum := sha256.Sum256([]byte(verifyTestContent))
+	expected := hex.EncodeToString(sum[:])
+
+	if err := File(path, Options{Sha256: expected}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFile_Sha256MismatchIsReported(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	err := File(path, Options{Sha256: strings.Repeat("0", 64)})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestFile_Sha512Match(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	sum := sha512.Sum512([]byte(verifyTestContent))
+	expected := hex.EncodeToString(sum[:])
+
+	if err := File(path, Options{Sha512: expected}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFile_Sha512MismatchIsReported(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	err := File(path, Options{Sha512: strings.Repeat("0", 128)})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestFile_UnknownSignatureBackend(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	err := File(path, Options{Signature: &Signature{Kind: "not-a-real-backend"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown signature backend, got nil")
+	}
+}
+
+func TestFile_SignatureBackendIsConsulted(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	var gotPath string
+	var gotSig Signature
+
+	orig := backends["cosign"]
+	backends["cosign"] = fakeVerifyBackend{fn: func(filePath string, sig Signature) error {
+		gotPath, gotSig = filePath, sig
+		return nil
+	}}
+	defer func() { backends["cosign"] = orig }()
+
+	sig := Signature{Kind: "cosign", PublicKey: "key.pub", URL: "https://example.com/sig"}
+	if err := File(path, Options{Signature: &sig}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotPath != path || gotSig != sig {
+		t.Fatalf("backend got (%v, %+v), want (%v, %+v)", gotPath, gotSig, path, sig)
+	}
+}
+
+func TestFile_SignatureBackendErrorIsWrapped(t *testing.T) {
+	path := writeVerifyTestFile(t)
+
+	orig := backends["cosign"]
+	backends["cosign"] = fakeVerifyBackend{fn: func(string, Signature) error {
+		return errSignatureMismatch
+	}}
+	defer func() { backends["cosign"] = orig }()
+
+	err := File(path, Options{Signature: &Signature{Kind: "cosign"}})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+var errSignatureMismatch = &signatureError{"signature does not match"}
+
+type signatureError struct{ msg string }
+
+func (e *signatureError) Error() string { return e.msg }
+
+type fakeVerifyBackend struct {
+	fn func(filePath string, sig Signature) error
+}
+
+func (f fakeVerifyBackend) verify(filePath string, sig Signature) error {
+	return f.fn(filePath, sig)
+}
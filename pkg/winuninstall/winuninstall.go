@@ -0,0 +1,121 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package winuninstall reads the "Add/Remove Programs" entries that Windows installers register
+// under the Uninstall registry key, so just-install can reconcile what's actually on a machine
+// against what it knows how to install.
+package winuninstall
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// uninstallKeys are the registry locations Windows installers register themselves under, covering
+// both per-machine and per-user installs, and both native and WOW64 32-bit installs on a 64-bit OS.
+var uninstallKeys = []struct {
+	root registry.Key
+	path string
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`},
+}
+
+// Entry is a single program registered in the Windows Uninstall registry key.
+type Entry struct {
+	DisplayName          string
+	DisplayVersion       string
+	UninstallString      string
+	QuietUninstallString string
+}
+
+// List returns every program currently registered in the Windows Uninstall registry key.
+func List() ([]Entry, error) {
+	var ret []Entry
+
+	for _, loc := range uninstallKeys {
+		key, err := registry.OpenKey(loc.root, loc.path, registry.READ)
+		if err != nil {
+			// Not every key exists on every machine (e.g. no 32-bit subsystem), skip it.
+			continue
+		}
+
+		names, err := key.ReadSubKeyNames(-1)
+		if err != nil {
+			key.Close()
+			return nil, err
+		}
+
+		for _, name := range names {
+			entry, ok := readEntry(loc.root, loc.path+`\`+name)
+			if ok {
+				ret = append(ret, entry)
+			}
+		}
+
+		key.Close()
+	}
+
+	return ret, nil
+}
+
+// Find returns the Uninstall entry whose DisplayName exactly matches (case-insensitively, ignoring
+// leading/trailing whitespace) displayName, falling back to pkgName if displayName is empty, and
+// whether one was found. Callers should pass a package's registry4.Package.DisplayName as
+// displayName when it has one set, since a package's own registry key rarely matches the
+// installer's real DisplayName (the fallback is only a best effort for packages that haven't
+// declared one). Plain substring containment isn't used here, as it both false-positives against
+// unrelated programs (a short key like "go" would contain-match "Google Chrome") and isn't any
+// more likely to find genuine matches than an exact one.
+func Find(entries []Entry, pkgName, displayName string) (Entry, bool) {
+	name := strings.TrimSpace(displayName)
+	if name == "" {
+		name = strings.TrimSpace(pkgName)
+	}
+
+	for _, e := range entries {
+		if strings.EqualFold(strings.TrimSpace(e.DisplayName), name) {
+			return e, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+func readEntry(root registry.Key, path string) (Entry, bool) {
+	key, err := registry.OpenKey(root, path, registry.READ)
+	if err != nil {
+		return Entry{}, false
+	}
+	defer key.Close()
+
+	displayName, _, err := key.GetStringValue("DisplayName")
+	if err != nil || displayName == "" {
+		return Entry{}, false
+	}
+
+	displayVersion, _, _ := key.GetStringValue("DisplayVersion")
+	uninstallString, _, _ := key.GetStringValue("UninstallString")
+	quietUninstallString, _, _ := key.GetStringValue("QuietUninstallString")
+
+	return Entry{
+		DisplayName:          displayName,
+		DisplayVersion:       displayVersion,
+		UninstallString:      uninstallString,
+		QuietUninstallString: quietUninstallString,
+	}, true
+}
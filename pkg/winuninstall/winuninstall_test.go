@@ -0,0 +1,57 @@
+// just-install - The simple package installer for Windows
+// Copyright (C) 2020 just-install authors.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 3 of the License.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package winuninstall
+
+import "testing"
+
+var entries = []Entry{
+	{DisplayName: "Google Chrome"},
+	{DisplayName: "GIMP 2.10.36"},
+}
+
+func TestFind_ExactMatchIgnoringCaseAndWhitespace(t *testing.T) {
+	got, ok := Find(entries, "gimp 2.10.36", "")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+
+	if got.DisplayName != "GIMP 2.10.36" {
+		t.Fatalf("got %+v, want GIMP 2.10.36", got)
+	}
+}
+
+func TestFind_DoesNotSubstringMatchUnrelatedPrograms(t *testing.T) {
+	if _, ok := Find(entries, "go", ""); ok {
+		t.Fatal("\"go\" should not match \"Google Chrome\" by substring")
+	}
+}
+
+func TestFind_PrefersExplicitDisplayNameOverPkgName(t *testing.T) {
+	got, ok := Find(entries, "gimp", "Google Chrome")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+
+	if got.DisplayName != "Google Chrome" {
+		t.Fatalf("got %+v, want Google Chrome (the explicit override, not the pkg name)", got)
+	}
+}
+
+func TestFind_NoMatch(t *testing.T) {
+	if _, ok := Find(entries, "nonexistent", ""); ok {
+		t.Fatal("expected no match")
+	}
+}